@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+
+	"github.com/magic-bear/go_to_sheets/pkg/auth"
+	"github.com/magic-bear/go_to_sheets/pkg/metrics"
+	"github.com/magic-bear/go_to_sheets/pkg/sheets"
+	"github.com/magic-bear/go_to_sheets/pkg/source"
+)
+
+// runDaemon keeps the process alive, running each loader on its own cron
+// schedule declared under loaders.<name>.schedule, until it receives
+// SIGINT/SIGTERM. SIGHUP re-reads config.yml and rebuilds the schedule
+// without a restart.
+func runDaemon(ctx context.Context, sheetsService *sheets.Service, sources map[string]source.Source, provider auth.Provider) {
+	metricsAddr := viper.GetString("daemon.metrics_addr")
+	if metricsAddr == "" {
+		metricsAddr = ":9090"
+	}
+	go func() {
+		if err := metrics.Serve(metricsAddr); err != nil {
+			log.Fatalf("metrics server: %v", err)
+		}
+	}()
+
+	running := &sync.Map{}
+	c := buildSchedule(ctx, sheetsService, sources, provider, running)
+	c.Start()
+
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	sigterm := make(chan os.Signal, 1)
+	signal.Notify(sigterm, syscall.SIGINT, syscall.SIGTERM)
+
+	for {
+		select {
+		case <-sighup:
+			log.Info("SIGHUP received, reloading config.yml")
+			config()
+			c.Stop()
+			c = buildSchedule(ctx, sheetsService, sources, provider, running)
+			c.Start()
+		case <-sigterm:
+			log.Info("shutting down daemon, waiting for in-flight runs to finish")
+			<-c.Stop().Done()
+			return
+		}
+	}
+}
+
+// buildSchedule reads loaders.<name>.schedule out of the current config and
+// returns a cron.Cron, not yet started, with one entry per scheduled
+// loader; the caller starts it.
+func buildSchedule(ctx context.Context, sheetsService *sheets.Service, sources map[string]source.Source, provider auth.Provider, running *sync.Map) *cron.Cron {
+	c := cron.New(cron.WithSeconds())
+	for loader := range viper.GetStringMap("loaders") {
+		loader := loader
+		schedule := viper.GetString("loaders." + loader + ".schedule")
+		if schedule == "" {
+			continue
+		}
+		if _, err := c.AddFunc(schedule, func() {
+			runScheduled(ctx, sheetsService, sources, provider, loader, running)
+		}); err != nil {
+			log.Errorf("loader %s: invalid schedule %q: %v", loader, schedule, err)
+		}
+	}
+	return c
+}
+
+// runScheduled wraps runLoader with skip-if-running semantics and the
+// structured run metrics/logging the daemon reports per run.
+func runScheduled(ctx context.Context, sheetsService *sheets.Service, sources map[string]source.Source, provider auth.Provider, loader string, running *sync.Map) {
+	if !acquireRun(running, loader) {
+		log.Warnf("loader %s: skipping run, previous run still in progress", loader)
+		return
+	}
+	defer running.Delete(loader)
+
+	start := time.Now()
+	rows, err := runLoader(ctx, sheetsService, sources, loader, false)
+	duration := time.Since(start)
+
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+		err = explainAuthError(provider, err)
+	}
+	metrics.RunsTotal.WithLabelValues(loader, outcome).Inc()
+	metrics.RowsWrittenTotal.WithLabelValues(loader).Add(float64(rows))
+	metrics.RunDuration.WithLabelValues(loader).Observe(duration.Seconds())
+
+	fields := log.Fields{
+		"loader":      loader,
+		"rows":        rows,
+		"duration_ms": duration.Milliseconds(),
+		"range":       viper.GetString("loaders." + loader + ".range"),
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+		log.WithFields(fields).Error("loader run failed")
+		return
+	}
+	log.WithFields(fields).Info("loader run complete")
+}
+
+// acquireRun marks loader as running in running, returning false without
+// side effects if a previous run hasn't called running.Delete yet.
+func acquireRun(running *sync.Map, loader string) bool {
+	_, alreadyRunning := running.LoadOrStore(loader, struct{}{})
+	return !alreadyRunning
+}
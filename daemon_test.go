@@ -0,0 +1,25 @@
+package main
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestAcquireRun(t *testing.T) {
+	running := &sync.Map{}
+
+	if !acquireRun(running, "loader1") {
+		t.Fatal("acquireRun() = false on first call, want true")
+	}
+	if acquireRun(running, "loader1") {
+		t.Fatal("acquireRun() = true while a run is in progress, want false")
+	}
+	if !acquireRun(running, "loader2") {
+		t.Fatal("acquireRun() = false for a different loader, want true")
+	}
+
+	running.Delete("loader1")
+	if !acquireRun(running, "loader1") {
+		t.Fatal("acquireRun() = false after the prior run released, want true")
+	}
+}
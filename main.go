@@ -1,76 +1,36 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/http"
 	"os"
-
-	"database/sql"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
-	sheets "google.golang.org/api/sheets/v4"
+	sheetsapi "google.golang.org/api/sheets/v4"
+
+	"github.com/magic-bear/go_to_sheets/pkg/auth"
+	"github.com/magic-bear/go_to_sheets/pkg/sheets"
+	"github.com/magic-bear/go_to_sheets/pkg/source"
 
-	_ "github.com/lib/pq"
 	"golang.org/x/net/context"
-	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/google"
 )
 
-// getClient uses a Context and Config to retrieve a Token
-// then generate a Client. It returns the generated Client.
-func getClient(ctx context.Context, config *oauth2.Config) *http.Client {
-	cacheFile := "./cache.json"
-	tok, err := tokenFromFile(cacheFile)
-	if err != nil {
-		tok = getTokenFromWeb(config)
-		saveToken(cacheFile, tok)
-	}
-	return config.Client(ctx, tok)
-}
-
-// getTokenFromWeb uses Config to request a Token.
-// It returns the retrieved Token.
-func getTokenFromWeb(config *oauth2.Config) *oauth2.Token {
-	authURL := config.AuthCodeURL("state-token", oauth2.AccessTypeOffline)
-	fmt.Printf("Go to the following link in your browser then type the "+
-		"authorization code: \n%v\n", authURL)
-
-	var code string
-	if _, err := fmt.Scan(&code); err != nil {
-		log.Fatalf("Unable to read authorization code %v", err)
-	}
-
-	tok, err := config.Exchange(oauth2.NoContext, code)
-	if err != nil {
-		log.Fatalf("Unable to retrieve token from web %v", err)
-	}
-	return tok
-}
-
-// tokenFromFile retrieves a Token from a given file path.
-// It returns the retrieved Token and any read error encountered.
-func tokenFromFile(file string) (*oauth2.Token, error) {
-	f, err := os.Open(file)
-	if err != nil {
-		return nil, err
-	}
-	t := &oauth2.Token{}
-	err = json.NewDecoder(f).Decode(t)
-	defer f.Close()
-	return t, err
-}
+const tokenCacheFile = "./cache.json"
 
-func saveToken(file string, token *oauth2.Token) {
-	fmt.Printf("Saving credential file to: %s\n", file)
-	f, err := os.Create(file)
-	if err != nil {
-		log.Fatalf("Unable to cache oauth token: %v", err)
+// authProvider selects the auth.Provider implementation declared by
+// google.auth.mode in config: service_account for unattended deployments,
+// or user_oauth (the default) for interactive use.
+func authProvider() auth.Provider {
+	switch viper.GetString("google.auth.mode") {
+	case "service_account":
+		return auth.ServiceAccount{KeyFile: viper.GetString("google.auth.service_account_key_file")}
+	default:
+		return auth.UserOAuth{
+			ClientSecretFile: "client_secret.json",
+			CacheFile:        tokenCacheFile,
+		}
 	}
-	defer f.Close()
-	json.NewEncoder(f).Encode(token)
 }
 
 type body struct {
@@ -100,101 +60,296 @@ func init() {
 func main() {
 
 	ctx := context.Background()
-	b, err := ioutil.ReadFile("client_secret.json")
+	provider := authProvider()
+
+	if len(os.Args) > 1 && os.Args[1] == "--auth" {
+		userOAuth, ok := provider.(auth.UserOAuth)
+		if !ok {
+			log.Fatalf("--auth only applies to google.auth.mode: user_oauth")
+		}
+		if _, err := userOAuth.Client(ctx); err != nil {
+			log.Fatalf("Auth flow failed: %v", err)
+		}
+		fmt.Println("Authenticated. Token cached at", userOAuth.CacheFile)
+		return
+	}
+
+	client, err := provider.Client(ctx)
 	if err != nil {
-		log.Fatalf("Unable to read client secret file: %v", err)
+		log.Fatalf("Unable to authenticate: %v", err)
 	}
-	config, err := google.ConfigFromJSON(b, "https://www.googleapis.com/auth/spreadsheets")
+	rawService, err := sheetsapi.New(client)
 	if err != nil {
-		log.Fatalf("Unable to parse client secret file to config: %v", err)
+		log.Fatalf("Unable to retrieve Sheets Client %v", err)
 	}
+	sheetsService := sheets.New(rawService)
 
-	client := getClient(ctx, config)
-	sheetsService, err := sheets.New(client)
+	sources, err := loadSources()
 	if err != nil {
-		log.Fatalf("Unable to retrieve Sheets Client %v", err)
+		log.Fatalf("Unable to init sources: %v", err)
+	}
+	defer closeSources(sources)
+
+	if len(os.Args) > 1 && os.Args[1] == "--daemon" {
+		runDaemon(ctx, sheetsService, sources, provider)
+		return
+	}
+
+	dryRun := false
+	for _, a := range os.Args[1:] {
+		if a == "--dry-run" {
+			dryRun = true
+		}
 	}
 
 	loaders := viper.GetStringMap("loaders")
 	for loader := range loaders {
 		log.Infof("Running Loader %s", loader)
-		spreadsheetId := viper.GetString("loaders." + loader + ".sheet")
-		rangeData := viper.GetString("loaders.ulta.range")
-
-		db := DbConnect()
-		rows, _ := db.Query(viper.GetString("loaders." + loader + ".query"))
-		defer rows.Close()
-
-		columns, _ := rows.Columns()
-		headers := make([]interface{}, len(columns))
-		for i := range columns {
-			headers[i] = columns[i]
-		}
-		sheetValues := [][]interface{}{headers}
-		count := len(columns)
-		values := make([]interface{}, count)
-		valuePtrs := make([]interface{}, count)
-
-		for rows.Next() {
-			for i := range columns {
-				valuePtrs[i] = &values[i]
-			}
-			var x []interface{}
-			switch err := rows.Scan(valuePtrs...); err {
-			case sql.ErrNoRows:
-				panic("something went wrong..")
-			case nil:
-				for i, _ := range columns {
-					val := values[i]
-
-					b, ok := val.([]byte)
-					var v interface{}
-					if ok {
-						v = string(b)
-					} else {
-						v = val
-					}
-					x = append(x, v)
-				}
-				sheetValues = append(sheetValues, x)
-			default:
-				panic("row scan failure")
-			}
+		if _, err := runLoader(ctx, sheetsService, sources, loader, dryRun); err != nil {
+			log.Errorf("Loader %s: %v", loader, explainAuthError(provider, err))
+			closeSources(sources)
+			os.Exit(1)
 		}
+	}
+}
 
-		rb := &sheets.BatchUpdateValuesRequest{
-			ValueInputOption: "USER_ENTERED",
+// loadSources builds a Source for every entry under the top-level
+// `sources:` map in config.yml, keyed by name.
+func loadSources() (map[string]source.Source, error) {
+	raw := viper.GetStringMap("sources")
+	sources := make(map[string]source.Source, len(raw))
+	for name := range raw {
+		var cfg source.Config
+		if err := viper.UnmarshalKey("sources."+name, &cfg); err != nil {
+			return nil, fmt.Errorf("source %s: %w", name, err)
 		}
-		rb.Data = append(rb.Data, &sheets.ValueRange{
-			Range:  rangeData,
-			Values: sheetValues,
-		})
-		_, err = sheetsService.Spreadsheets.Values.BatchUpdate(spreadsheetId, rb).Context(ctx).Do()
+		src, err := source.New(cfg)
 		if err != nil {
-			log.Fatal(err)
+			return nil, fmt.Errorf("source %s: %w", name, err)
 		}
-		fmt.Println("Done.")
+		sources[name] = src
 	}
+	return sources, nil
 }
 
-func DbConnect() *sql.DB {
+// explainAuthError adds a "has the spreadsheet been shared with the
+// service account" hint to API errors when provider authenticates as a
+// service account, since that's the most common cause of a 403 in that
+// mode.
+func explainAuthError(provider auth.Provider, err error) error {
+	sa, ok := provider.(auth.ServiceAccount)
+	if !ok || err == nil {
+		return err
+	}
+	email, emailErr := auth.ServiceAccountEmail(sa.KeyFile)
+	if emailErr != nil {
+		return err
+	}
+	return auth.ExplainPermissionError(err, email)
+}
+
+func closeSources(sources map[string]source.Source) {
+	for name, src := range sources {
+		if err := src.Close(); err != nil {
+			log.Warnf("closing source %s: %v", name, err)
+		}
+	}
+}
+
+// defaultBatchSize is the chunk size used when a loader doesn't declare
+// batch_size.
+const defaultBatchSize = 5000
+
+// runLoader executes a single loader's query against its configured source
+// and streams the results into its spreadsheet in batch_size-row chunks,
+// according to the loader's configured mode: the first chunk clears the
+// target (overwrite) or creates a new tab (new-tab-per-run) and writes the
+// header, or, in append mode, is appended after the existing rows, with the
+// header written only when the range is empty; later chunks extend the
+// target with AppendCells. In dryRun, no Sheets API calls are
+// made; rows are counted and the request size estimated instead. It
+// returns the number of data rows written (or that would be written).
+func runLoader(ctx context.Context, sheetsService *sheets.Service, sources map[string]source.Source, loader string, dryRun bool) (int, error) {
+	spreadsheetId := viper.GetString("loaders." + loader + ".sheet")
+	rangeData := viper.GetString("loaders." + loader + ".range")
+	mode := sheets.Mode(viper.GetString("loaders." + loader + ".mode"))
+	if mode == "" {
+		mode = sheets.ModeOverwrite
+	}
+	batchSize := viper.GetInt("loaders." + loader + ".batch_size")
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
 
-	psqlInfo := fmt.Sprintf("host=%s port=%d user=%s "+
-		"password=%s dbname=%s",
-		viper.GetString("database.host"), viper.GetInt("database.port"), viper.GetString("database.user"), viper.GetString("database.password"), viper.GetString("database.dbname"))
-	db, err := sql.Open("postgres", psqlInfo)
+	sourceName := viper.GetString("loaders." + loader + ".source")
+	src, ok := sources[sourceName]
+	if !ok {
+		return 0, fmt.Errorf("unknown source %q", sourceName)
+	}
+
+	rows, err := src.Query(ctx, viper.GetString("loaders."+loader+".query"))
 	if err != nil {
-		log.Fatalf("Unable to connect to Postgres DB")
+		return 0, fmt.Errorf("query failed: %w", err)
 	}
+	defer rows.Close()
 
-	err = db.Ping()
+	columns, err := rows.Columns()
 	if err != nil {
-		log.Fatalf("Unable to connect to Postgres DB")
+		return 0, err
+	}
+	headers := make([]interface{}, len(columns))
+	for i := range columns {
+		headers[i] = columns[i]
+	}
+
+	var (
+		rowCount      int
+		requestCount  int
+		estimateBytes int
+		firstChunk    = true
+		sheetID       int64
+		existingRows  int
+		tabRange      = rangeData
+		chunk         = [][]interface{}{headers}
+	)
+
+	flush := func() error {
+		if len(chunk) == 0 {
+			return nil
+		}
+		requestCount++
+		if dryRun {
+			estimateBytes += sheets.EstimateRequestSize(chunk)
+			chunk = chunk[:0]
+			return nil
+		}
+
+		if firstChunk {
+			firstChunk = false
+			switch mode {
+			case sheets.ModeAppend:
+				existing, err := sheetsService.ReadRange(ctx, spreadsheetId, rangeData)
+				if err != nil {
+					return err
+				}
+				existingRows = len(existing)
+				body := chunk
+				if len(existing) > 0 {
+					// the header row is already present; only append data rows
+					body = chunk[1:]
+				}
+				if err := sheetsService.AppendCells(ctx, spreadsheetId, rangeData, body); err != nil {
+					return err
+				}
+			case sheets.ModeNewTabPerRun:
+				title := fmt.Sprintf("%s-%s", loader, time.Now().Format("20060102-150405"))
+				id, err := sheetsService.AddNewSheet(ctx, spreadsheetId, title)
+				if err != nil {
+					return err
+				}
+				sheetID = id
+				tabRange = fmt.Sprintf("%s!A1", title)
+				if err := sheetsService.WriteRange(ctx, spreadsheetId, tabRange, chunk); err != nil {
+					return err
+				}
+			default:
+				if err := sheetsService.ClearRange(ctx, spreadsheetId, rangeData); err != nil {
+					return err
+				}
+				if err := sheetsService.WriteRange(ctx, spreadsheetId, rangeData, chunk); err != nil {
+					return err
+				}
+			}
+			chunk = chunk[:0]
+			return nil
+		}
+
+		target := rangeData
+		if mode == sheets.ModeNewTabPerRun {
+			target = tabRange
+		}
+		if err := sheetsService.AppendCells(ctx, spreadsheetId, target, chunk); err != nil {
+			return err
+		}
+		chunk = chunk[:0]
+		return nil
+	}
+
+	for rows.Next() {
+		values, err := rows.Values()
+		if err != nil {
+			return 0, fmt.Errorf("row scan failure: %w", err)
+		}
+		chunk = append(chunk, sheets.ValuesToCellData(values))
+		rowCount++
+		if len(chunk) >= batchSize {
+			if err := flush(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if err := flush(); err != nil {
+		return 0, err
+	}
+
+	if dryRun {
+		log.Infof("Loader %s: dry run — %d rows, ~%d bytes across %d request(s)", loader, rowCount, estimateBytes, requestCount)
+		return rowCount, nil
 	}
 
-	log.Debug("You are Successfully connected!")
+	if viper.IsSet("loaders." + loader + ".format") {
+		formatSheetID := sheetID
+		if mode != sheets.ModeNewTabPerRun {
+			id, err := sheetsService.SheetIDForRange(ctx, spreadsheetId, rangeData)
+			if err != nil {
+				return 0, err
+			}
+			formatSheetID = id
+		}
+		dataStartRow, dataEndRow := 1, rowCount+1
+		if mode == sheets.ModeAppend {
+			// existingRows already includes the header row from a prior
+			// run, so the data this run wrote starts there, not at row 1.
+			dataStartRow = existingRows
+			if dataStartRow == 0 {
+				dataStartRow = 1
+			}
+			dataEndRow = dataStartRow + rowCount
+		}
+		if err := applyLoaderFormat(ctx, sheetsService, loader, spreadsheetId, formatSheetID, dataStartRow, dataEndRow); err != nil {
+			return 0, err
+		}
+	}
+
+	fmt.Println("Done.")
+	return rowCount, nil
+}
+
+// applyLoaderFormat applies the optional format block declared under
+// loaders.<loader>.format, if any. dataStartRow and dataEndRow are the
+// zero-indexed sheet rows the number-format requests should cover.
+func applyLoaderFormat(ctx context.Context, sheetsService *sheets.Service, loader, spreadsheetId string, sheetID int64, dataStartRow, dataEndRow int) error {
+	if !viper.IsSet("loaders." + loader + ".format") {
+		return nil
+	}
+
+	spec := sheets.FormatSpec{
+		SheetID:       sheetID,
+		HeaderRow:     viper.GetBool("loaders." + loader + ".format.bold_header"),
+		NumberFormats: map[int]string{},
+	}
+	for col, pattern := range viper.GetStringMapString("loaders." + loader + ".format.number_formats") {
+		idx := 0
+		if _, err := fmt.Sscanf(col, "%d", &idx); err != nil {
+			continue
+		}
+		spec.NumberFormats[idx] = pattern
+	}
 
-	return db
+	return sheetsService.ApplyFormat(ctx, spreadsheetId, dataStartRow, dataEndRow, spec)
 }
 
 func config() {
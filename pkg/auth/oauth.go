@@ -0,0 +1,176 @@
+// Package auth handles Google OAuth client construction for go_to_sheets,
+// including the interactive loopback login flow and on-disk token caching.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// UserOAuthClient returns an HTTP client authenticated as the local user. If
+// cacheFile already holds a token it is reused (and transparently refreshed);
+// otherwise the interactive loopback flow in Login is run first.
+func UserOAuthClient(ctx context.Context, config *oauth2.Config, cacheFile string) (*http.Client, error) {
+	tok, err := tokenFromFile(cacheFile)
+	if err != nil {
+		tok, err = Login(config)
+		if err != nil {
+			return nil, fmt.Errorf("oauth login: %w", err)
+		}
+		if err := saveToken(cacheFile, tok); err != nil {
+			return nil, fmt.Errorf("save token: %w", err)
+		}
+	}
+
+	ts := &persistingTokenSource{
+		cacheFile: cacheFile,
+		inner:     config.TokenSource(ctx, tok),
+	}
+	return oauth2.NewClient(ctx, oauth2.ReuseTokenSource(tok, ts)), nil
+}
+
+// persistingTokenSource wraps an oauth2.TokenSource and writes every token it
+// mints back to cacheFile, so a refreshed token survives process restarts and
+// long-running daemon invocations never need re-auth.
+type persistingTokenSource struct {
+	cacheFile string
+	inner     oauth2.TokenSource
+}
+
+func (p *persistingTokenSource) Token() (*oauth2.Token, error) {
+	tok, err := p.inner.Token()
+	if err != nil {
+		return nil, err
+	}
+	if err := saveToken(p.cacheFile, tok); err != nil {
+		return nil, fmt.Errorf("persist refreshed token: %w", err)
+	}
+	return tok, nil
+}
+
+// Login runs the interactive loopback OAuth flow: it starts a short-lived
+// HTTP server on 127.0.0.1, points the browser at the Google consent
+// screen, and waits for the redirect callback carrying the auth code.
+func Login(config *oauth2.Config) (*oauth2.Token, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("bind loopback listener: %w", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	state, err := randomState()
+	if err != nil {
+		return nil, err
+	}
+
+	redirectURL := fmt.Sprintf("http://127.0.0.1:%d/callback", port)
+	configCopy := *config
+	configCopy.RedirectURL = redirectURL
+
+	type result struct {
+		code string
+		err  error
+	}
+	resultCh := make(chan result, 1)
+
+	mux := http.NewServeMux()
+	server := &http.Server{Handler: mux}
+	mux.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("state") != state {
+			http.Error(w, "state mismatch", http.StatusBadRequest)
+			resultCh <- result{err: fmt.Errorf("oauth callback: state mismatch")}
+			return
+		}
+		if errParam := q.Get("error"); errParam != "" {
+			http.Error(w, errParam, http.StatusBadRequest)
+			resultCh <- result{err: fmt.Errorf("oauth callback: %s", errParam)}
+			return
+		}
+		code := q.Get("code")
+		fmt.Fprintln(w, "Authentication complete, you can close this tab.")
+		resultCh <- result{code: code}
+	})
+
+	go server.Serve(listener)
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(ctx)
+	}()
+
+	authURL := configCopy.AuthCodeURL(state, oauth2.AccessTypeOffline)
+	fmt.Printf("Opening browser for authorization. If it doesn't open, visit:\n%s\n", authURL)
+	if err := openBrowser(authURL); err != nil {
+		fmt.Printf("Unable to open browser automatically: %v\n", err)
+	}
+
+	select {
+	case res := <-resultCh:
+		if res.err != nil {
+			return nil, res.err
+		}
+		tok, err := configCopy.Exchange(context.Background(), res.code)
+		if err != nil {
+			return nil, fmt.Errorf("exchange code: %w", err)
+		}
+		return tok, nil
+	case <-time.After(5 * time.Minute):
+		return nil, fmt.Errorf("oauth login: timed out waiting for browser callback")
+	}
+}
+
+func randomState() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate state: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// openBrowser opens url in the user's default browser across the platforms
+// we support.
+func openBrowser(url string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", url).Start()
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", url).Start()
+	default:
+		return exec.Command("xdg-open", url).Start()
+	}
+}
+
+func tokenFromFile(file string) (*oauth2.Token, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	t := &oauth2.Token{}
+	if err := json.NewDecoder(f).Decode(t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func saveToken(file string, token *oauth2.Token) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return fmt.Errorf("unable to cache oauth token: %w", err)
+	}
+	defer f.Close()
+	return json.NewEncoder(f).Encode(token)
+}
@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"golang.org/x/oauth2/google"
+)
+
+// Provider builds an authenticated Sheets HTTP client. UserOAuth and
+// ServiceAccount are the two implementations, selected by
+// google.auth.mode in config.
+type Provider interface {
+	Client(ctx context.Context) (*http.Client, error)
+}
+
+// UserOAuth authenticates as the interactive local user via the loopback
+// OAuth flow, caching the resulting token on disk.
+type UserOAuth struct {
+	ClientSecretFile string
+	CacheFile        string
+}
+
+func (u UserOAuth) Client(ctx context.Context) (*http.Client, error) {
+	b, err := ioutil.ReadFile(u.ClientSecretFile)
+	if err != nil {
+		return nil, fmt.Errorf("read client secret file: %w", err)
+	}
+	config, err := google.ConfigFromJSON(b, spreadsheetsScope)
+	if err != nil {
+		return nil, fmt.Errorf("parse client secret file: %w", err)
+	}
+	return UserOAuthClient(ctx, config, u.CacheFile)
+}
+
+// ServiceAccount authenticates as a Google service account from a JSON key
+// file, for unattended deployments with no interactive user.
+type ServiceAccount struct {
+	KeyFile string
+}
+
+func (s ServiceAccount) Client(ctx context.Context) (*http.Client, error) {
+	return ServiceAccountClient(ctx, s.KeyFile)
+}
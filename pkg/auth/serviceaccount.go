@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/googleapi"
+)
+
+const spreadsheetsScope = "https://www.googleapis.com/auth/spreadsheets"
+
+// ServiceAccountClient builds an HTTP client authenticated as a Google
+// service account from a JSON key file. If keyFile is empty, it falls back
+// to the GOOGLE_APPLICATION_CREDENTIALS environment variable, matching how
+// other Google client libraries resolve application default credentials.
+func ServiceAccountClient(ctx context.Context, keyFile string) (*http.Client, error) {
+	if keyFile == "" {
+		keyFile = os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	}
+	if keyFile == "" {
+		return nil, fmt.Errorf("service account auth: no key file configured and GOOGLE_APPLICATION_CREDENTIALS is not set")
+	}
+
+	raw, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("read service account key %s: %w", keyFile, err)
+	}
+
+	jwtConfig, err := google.JWTConfigFromJSON(raw, spreadsheetsScope)
+	if err != nil {
+		return nil, fmt.Errorf("parse service account key %s: %w", keyFile, err)
+	}
+
+	return jwtConfig.Client(ctx), nil
+}
+
+// ServiceAccountEmail reads the client_email field out of a service-account
+// JSON key file, for use in permission-error messages.
+func ServiceAccountEmail(keyFile string) (string, error) {
+	if keyFile == "" {
+		keyFile = os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	}
+	raw, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return "", fmt.Errorf("read service account key %s: %w", keyFile, err)
+	}
+	jwtConfig, err := google.JWTConfigFromJSON(raw, spreadsheetsScope)
+	if err != nil {
+		return "", fmt.Errorf("parse service account key %s: %w", keyFile, err)
+	}
+	return jwtConfig.Email, nil
+}
+
+// ExplainPermissionError wraps err with a clearer hint when the Sheets API
+// rejects a request because the target spreadsheet hasn't been shared with
+// serviceAccountEmail.
+func ExplainPermissionError(err error, serviceAccountEmail string) error {
+	if err == nil {
+		return nil
+	}
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusForbidden {
+		return fmt.Errorf("%w (has the spreadsheet been shared with %s?)", err, serviceAccountEmail)
+	}
+	return err
+}
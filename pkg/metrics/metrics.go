@@ -0,0 +1,43 @@
+// Package metrics exposes the Prometheus counters and histogram the daemon
+// scheduler reports for each loader run.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	// RunsTotal counts loader runs, labeled by loader and outcome
+	// ("success" or "error").
+	RunsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "loader_runs_total",
+		Help: "Total number of loader runs.",
+	}, []string{"loader", "outcome"})
+
+	// RowsWrittenTotal counts rows written to spreadsheets, labeled by
+	// loader.
+	RowsWrittenTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "loader_rows_written_total",
+		Help: "Total number of rows written to spreadsheets.",
+	}, []string{"loader"})
+
+	// RunDuration observes loader run duration in seconds, labeled by
+	// loader.
+	RunDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "loader_run_duration_seconds",
+		Help:    "Loader run duration in seconds.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"loader"})
+)
+
+// Serve starts the /metrics HTTP endpoint on addr. It blocks, so callers
+// should run it in its own goroutine.
+func Serve(addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	return http.ListenAndServe(addr, mux)
+}
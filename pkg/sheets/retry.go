@@ -0,0 +1,58 @@
+package sheets
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+const maxRetries = 5
+
+// withRetry retries fn on transient Sheets API errors (429 rate limit, 503
+// unavailable), honoring the Retry-After header when the API sends one and
+// falling back to exponential backoff otherwise. This lets long,
+// chunked exports survive transient quota errors instead of failing the
+// whole run.
+func withRetry(fn func() error) error {
+	backoff := time.Second
+	var err error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		err = fn()
+		apiErr, retryable := err.(*googleapi.Error)
+		if err == nil || !retryable || (apiErr.Code != http.StatusTooManyRequests && apiErr.Code != http.StatusServiceUnavailable) {
+			return err
+		}
+		if attempt == maxRetries {
+			break
+		}
+
+		wait := backoff
+		if d := retryAfter(apiErr); d > 0 {
+			wait = d
+		}
+		time.Sleep(wait)
+		backoff *= 2
+	}
+	return err
+}
+
+// retryAfter extracts the Retry-After header from a Sheets API error, as
+// either a second count or an HTTP date.
+func retryAfter(apiErr *googleapi.Error) time.Duration {
+	if apiErr.Header == nil {
+		return 0
+	}
+	v := apiErr.Header.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
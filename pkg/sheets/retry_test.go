@@ -0,0 +1,82 @@
+package sheets
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+func TestRetryAfterSeconds(t *testing.T) {
+	apiErr := &googleapi.Error{
+		Code:   http.StatusTooManyRequests,
+		Header: http.Header{"Retry-After": []string{"30"}},
+	}
+	got := retryAfter(apiErr)
+	if got != 30*time.Second {
+		t.Errorf("retryAfter() = %v, want 30s", got)
+	}
+}
+
+func TestRetryAfterHTTPDate(t *testing.T) {
+	when := time.Now().Add(45 * time.Second).UTC().Truncate(time.Second)
+	apiErr := &googleapi.Error{
+		Code:   http.StatusServiceUnavailable,
+		Header: http.Header{"Retry-After": []string{when.Format(http.TimeFormat)}},
+	}
+	got := retryAfter(apiErr)
+	if got <= 0 || got > 46*time.Second {
+		t.Errorf("retryAfter() = %v, want roughly 45s", got)
+	}
+}
+
+func TestRetryAfterMissingOrInvalid(t *testing.T) {
+	cases := []struct {
+		name   string
+		apiErr *googleapi.Error
+	}{
+		{"nil header", &googleapi.Error{Code: http.StatusTooManyRequests}},
+		{"empty header", &googleapi.Error{Code: http.StatusTooManyRequests, Header: http.Header{}}},
+		{"garbage value", &googleapi.Error{Code: http.StatusTooManyRequests, Header: http.Header{"Retry-After": []string{"not-a-time"}}}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := retryAfter(tc.apiErr); got != 0 {
+				t.Errorf("retryAfter() = %v, want 0", got)
+			}
+		})
+	}
+}
+
+func TestWithRetryRetriesTransientErrors(t *testing.T) {
+	attempts := 0
+	err := withRetry(func() error {
+		attempts++
+		if attempts < 3 {
+			return &googleapi.Error{Code: http.StatusTooManyRequests}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("withRetry() = %v, want nil after eventual success", err)
+	}
+	if attempts != 3 {
+		t.Errorf("withRetry() made %d attempts, want 3", attempts)
+	}
+}
+
+func TestWithRetryDoesNotRetryNonTransientErrors(t *testing.T) {
+	attempts := 0
+	wantErr := &googleapi.Error{Code: http.StatusForbidden}
+	err := withRetry(func() error {
+		attempts++
+		return wantErr
+	})
+	if err != wantErr {
+		t.Fatalf("withRetry() = %v, want %v", err, wantErr)
+	}
+	if attempts != 1 {
+		t.Errorf("withRetry() made %d attempts, want 1 for a non-retryable error", attempts)
+	}
+}
@@ -0,0 +1,276 @@
+// Package sheets is a small wrapper around the generated Google Sheets API
+// client, giving loaders typed read/write/format operations instead of
+// hand-rolling BatchUpdate requests in main.
+package sheets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	sheetsapi "google.golang.org/api/sheets/v4"
+)
+
+// Mode controls how a loader writes its query results into a spreadsheet.
+type Mode string
+
+const (
+	// ModeOverwrite replaces the target range with today's result set.
+	ModeOverwrite Mode = "overwrite"
+	// ModeAppend reads the current last row and appends after it.
+	ModeAppend Mode = "append"
+	// ModeNewTabPerRun creates a timestamped tab and writes into it.
+	ModeNewTabPerRun Mode = "new-tab-per-run"
+)
+
+// Service wraps the generated Sheets API client with the operations the
+// loaders need.
+type Service struct {
+	api *sheetsapi.Service
+}
+
+// New wraps an already-authenticated Sheets API client.
+func New(api *sheetsapi.Service) *Service {
+	return &Service{api: api}
+}
+
+// ReadRange returns the values currently stored in rangeA1.
+func (s *Service) ReadRange(ctx context.Context, spreadsheetID, rangeA1 string) ([][]interface{}, error) {
+	var values [][]interface{}
+	err := withRetry(func() error {
+		resp, err := s.api.Spreadsheets.Values.Get(spreadsheetID, rangeA1).Context(ctx).Do()
+		if err != nil {
+			return err
+		}
+		values = resp.Values
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read range %s: %w", rangeA1, err)
+	}
+	return values, nil
+}
+
+// AddNewSheet creates a new tab named title and returns its sheetId.
+func (s *Service) AddNewSheet(ctx context.Context, spreadsheetID, title string) (int64, error) {
+	var sheetID int64
+	err := withRetry(func() error {
+		resp, err := s.api.Spreadsheets.BatchUpdate(spreadsheetID, &sheetsapi.BatchUpdateSpreadsheetRequest{
+			Requests: []*sheetsapi.Request{
+				{
+					AddSheet: &sheetsapi.AddSheetRequest{
+						Properties: &sheetsapi.SheetProperties{Title: title},
+					},
+				},
+			},
+		}).Context(ctx).Do()
+		if err != nil {
+			return err
+		}
+		sheetID = resp.Replies[0].AddSheet.Properties.SheetId
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("add sheet %q: %w", title, err)
+	}
+	return sheetID, nil
+}
+
+// SheetIDForRange resolves the sheetId of the tab named in rangeA1 (e.g.
+// "Sheet1!A1:C10" or a bare "Sheet1"), for callers that need a sheetId to
+// format an existing tab rather than one just created by AddNewSheet.
+func (s *Service) SheetIDForRange(ctx context.Context, spreadsheetID, rangeA1 string) (int64, error) {
+	title := rangeA1
+	if i := strings.Index(rangeA1, "!"); i >= 0 {
+		title = rangeA1[:i]
+	}
+	title = strings.Trim(title, "'")
+
+	var sheetID int64
+	err := withRetry(func() error {
+		resp, err := s.api.Spreadsheets.Get(spreadsheetID).Context(ctx).Do()
+		if err != nil {
+			return err
+		}
+		for _, sh := range resp.Sheets {
+			if sh.Properties.Title == title {
+				sheetID = sh.Properties.SheetId
+				return nil
+			}
+		}
+		return fmt.Errorf("no sheet named %q", title)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("resolve sheet id for range %s: %w", rangeA1, err)
+	}
+	return sheetID, nil
+}
+
+// ClearRange blanks out rangeA1 without touching formatting.
+func (s *Service) ClearRange(ctx context.Context, spreadsheetID, rangeA1 string) error {
+	err := withRetry(func() error {
+		_, err := s.api.Spreadsheets.Values.Clear(spreadsheetID, rangeA1, &sheetsapi.ClearValuesRequest{}).Context(ctx).Do()
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("clear range %s: %w", rangeA1, err)
+	}
+	return nil
+}
+
+// WriteRange overwrites rangeA1 with values in a single batch update. This
+// is today's overwrite behavior.
+func (s *Service) WriteRange(ctx context.Context, spreadsheetID, rangeA1 string, values [][]interface{}) error {
+	rb := &sheetsapi.BatchUpdateValuesRequest{ValueInputOption: "USER_ENTERED"}
+	rb.Data = append(rb.Data, &sheetsapi.ValueRange{Range: rangeA1, Values: values})
+	err := withRetry(func() error {
+		_, err := s.api.Spreadsheets.Values.BatchUpdate(spreadsheetID, rb).Context(ctx).Do()
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("write range %s: %w", rangeA1, err)
+	}
+	return nil
+}
+
+// AppendCells appends rows after the last row of rangeA1.
+func (s *Service) AppendCells(ctx context.Context, spreadsheetID, rangeA1 string, values [][]interface{}) error {
+	vr := &sheetsapi.ValueRange{Values: values}
+	err := withRetry(func() error {
+		_, err := s.api.Spreadsheets.Values.Append(spreadsheetID, rangeA1, vr).
+			ValueInputOption("USER_ENTERED").
+			InsertDataOption("INSERT_ROWS").
+			Context(ctx).
+			Do()
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("append to range %s: %w", rangeA1, err)
+	}
+	return nil
+}
+
+// FormatSpec describes optional per-column formatting applied after a
+// values write.
+type FormatSpec struct {
+	SheetID int64
+	// HeaderRow bolds row 1.
+	HeaderRow bool
+	// NumberFormats maps a zero-based column index to a number format
+	// pattern, e.g. "#,##0.00", applied to the rows between the
+	// dataStartRow and dataEndRow passed to ApplyFormat.
+	NumberFormats map[int]string
+}
+
+// ApplyFormat issues a BatchUpdate of RepeatCellRequests for the header row
+// and any per-column number formats in spec. dataStartRow and dataEndRow
+// are zero-indexed sheet rows bounding the number-format range; the caller
+// computes them relative to the sheet's actual current contents (not just
+// the rows written this run), so a second append run formats only the
+// rows it just wrote rather than re-formatting row 0 onward every time.
+func (s *Service) ApplyFormat(ctx context.Context, spreadsheetID string, dataStartRow, dataEndRow int, spec FormatSpec) error {
+	var requests []*sheetsapi.Request
+
+	if spec.HeaderRow {
+		requests = append(requests, &sheetsapi.Request{
+			RepeatCell: &sheetsapi.RepeatCellRequest{
+				Range: &sheetsapi.GridRange{
+					SheetId:    spec.SheetID,
+					StartRowIndex: 0,
+					EndRowIndex:   1,
+				},
+				Cell: &sheetsapi.CellData{
+					UserEnteredFormat: &sheetsapi.CellFormat{
+						TextFormat: &sheetsapi.TextFormat{Bold: true},
+					},
+				},
+				Fields: "userEnteredFormat.textFormat.bold",
+			},
+		})
+	}
+
+	for col, pattern := range spec.NumberFormats {
+		requests = append(requests, &sheetsapi.Request{
+			RepeatCell: &sheetsapi.RepeatCellRequest{
+				Range: &sheetsapi.GridRange{
+					SheetId:          spec.SheetID,
+					StartRowIndex:    int64(dataStartRow),
+					EndRowIndex:      int64(dataEndRow),
+					StartColumnIndex: int64(col),
+					EndColumnIndex:   int64(col) + 1,
+				},
+				Cell: &sheetsapi.CellData{
+					UserEnteredFormat: &sheetsapi.CellFormat{
+						NumberFormat: &sheetsapi.NumberFormat{
+							Type:    "NUMBER",
+							Pattern: pattern,
+						},
+					},
+				},
+				Fields: "userEnteredFormat.numberFormat",
+			},
+		})
+	}
+
+	if len(requests) == 0 {
+		return nil
+	}
+
+	err := withRetry(func() error {
+		_, err := s.api.Spreadsheets.BatchUpdate(spreadsheetID, &sheetsapi.BatchUpdateSpreadsheetRequest{
+			Requests: requests,
+		}).Context(ctx).Do()
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("apply format: %w", err)
+	}
+	return nil
+}
+
+// ValuesToCellData normalizes one sql.Scan'd row into the plain Go values
+// WriteRange/AppendCells send under ValueInputOption "USER_ENTERED", so
+// Sheets' own input parser — rather than this package — decides whether a
+// cell becomes a number, a date, or a formula (a string leading with "="
+// is parsed as one; there is no separate formula case to handle here).
+func ValuesToCellData(row []interface{}) []interface{} {
+	out := make([]interface{}, len(row))
+	for i, v := range row {
+		out[i] = cellValue(v)
+	}
+	return out
+}
+
+// cellValue normalizes a single scanned value into the Go type
+// encoding/json (and so the Sheets API client) turns into the matching
+// value: a JSON number, boolean, or string. time.Time is formatted as
+// "2006-01-02 15:04:05" rather than RFC3339, since that's the layout
+// USER_ENTERED's locale date parser recognizes — the "T"/"Z" separators
+// in RFC3339 are not.
+func cellValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(t)
+	case time.Time:
+		return t.Format("2006-01-02 15:04:05")
+	case bool, int, int32, int64, float32, float64:
+		return t
+	default:
+		return fmt.Sprintf("%v", t)
+	}
+}
+
+// EstimateRequestSize returns the approximate JSON-encoded size in bytes of
+// a values write for rows, used by dry-run mode to estimate request size
+// against the Sheets API's 10MB request limit without calling the API.
+func EstimateRequestSize(rows [][]interface{}) int {
+	encoded, err := json.Marshal(rows)
+	if err != nil {
+		return 0
+	}
+	return len(encoded)
+}
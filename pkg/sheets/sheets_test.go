@@ -0,0 +1,36 @@
+package sheets
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCellValue(t *testing.T) {
+	refTime := time.Date(2026, time.July, 29, 9, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name string
+		in   interface{}
+		want interface{}
+	}{
+		{"nil becomes empty string", nil, ""},
+		{"byte slice becomes string", []byte("hello"), "hello"},
+		{"time.Time becomes a Sheets-parseable date string", refTime, refTime.Format("2006-01-02 15:04:05")},
+		{"bool passes through", true, true},
+		{"int passes through", 42, 42},
+		{"int32 passes through", int32(42), int32(42)},
+		{"int64 passes through", int64(42), int64(42)},
+		{"float32 passes through", float32(4.2), float32(4.2)},
+		{"float64 passes through", 4.2, 4.2},
+		{"unhandled type falls back to fmt.Sprintf", []int{1, 2}, "[1 2]"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := cellValue(tc.in)
+			if got != tc.want {
+				t.Errorf("cellValue(%#v) = %#v, want %#v", tc.in, got, tc.want)
+			}
+		})
+	}
+}
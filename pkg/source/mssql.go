@@ -0,0 +1,33 @@
+package source
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "github.com/denisenkom/go-mssqldb"
+)
+
+// NewMSSQL opens a SQL Server source.
+func NewMSSQL(cfg DSNConfig) (Source, error) {
+	dsn := fmt.Sprintf("sqlserver://%s:%s@%s:%d?database=%s",
+		cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.DBName)
+
+	db, err := sql.Open("sqlserver", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open mssql: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("ping mssql: %w", err)
+	}
+	return &sqlSource{db: db, normalize: normalizeMSSQL}, nil
+}
+
+// normalizeMSSQL forces go-mssqldb timestamps to UTC so runs are
+// consistent regardless of the server's configured timezone.
+func normalizeMSSQL(dbType string, v interface{}) interface{} {
+	if t, ok := v.(time.Time); ok {
+		return t.UTC()
+	}
+	return v
+}
@@ -0,0 +1,33 @@
+package source
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNormalizeMSSQL(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+	local := time.Date(2026, time.July, 29, 9, 0, 0, 0, loc)
+
+	got := normalizeMSSQL("DATETIME2", local)
+	ts, ok := got.(time.Time)
+	if !ok {
+		t.Fatalf("normalizeMSSQL returned %T, want time.Time", got)
+	}
+	if ts.Location() != time.UTC {
+		t.Errorf("normalizeMSSQL did not convert to UTC: got location %v", ts.Location())
+	}
+	if !ts.Equal(local) {
+		t.Errorf("normalizeMSSQL changed the instant: got %v, want %v", ts, local)
+	}
+}
+
+func TestNormalizeMSSQLPassthrough(t *testing.T) {
+	got := normalizeMSSQL("INT", 42)
+	if got != 42 {
+		t.Errorf("normalizeMSSQL(%q, 42) = %#v, want 42", "INT", got)
+	}
+}
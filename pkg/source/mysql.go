@@ -0,0 +1,41 @@
+package source
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	_ "github.com/go-sql-driver/mysql"
+)
+
+// NewMySQL opens a MySQL source.
+func NewMySQL(cfg DSNConfig) (Source, error) {
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true",
+		cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.DBName)
+
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open mysql: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("ping mysql: %w", err)
+	}
+	return &sqlSource{db: db, normalize: normalizeMySQL}, nil
+}
+
+// normalizeMySQL converts the go-sql-driver/mysql []byte encoding of
+// numeric columns into a float64, so the sheet writer gets a typed number
+// instead of a quoted string.
+func normalizeMySQL(dbType string, v interface{}) interface{} {
+	b, ok := v.([]byte)
+	if !ok {
+		return v
+	}
+	switch dbType {
+	case "DECIMAL", "NUMERIC", "BIGINT", "INT", "MEDIUMINT", "SMALLINT", "TINYINT", "DOUBLE", "FLOAT":
+		if f, err := strconv.ParseFloat(string(b), 64); err == nil {
+			return f
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,28 @@
+package source
+
+import "testing"
+
+func TestNormalizeMySQL(t *testing.T) {
+	cases := []struct {
+		name   string
+		dbType string
+		in     interface{}
+		want   interface{}
+	}{
+		{"decimal bytes convert to float64", "DECIMAL", []byte("12.50"), 12.50},
+		{"bigint bytes convert to float64", "BIGINT", []byte("9001"), float64(9001)},
+		{"varchar bytes stay a string", "VARCHAR", []byte("hello"), "hello"},
+		{"unparseable numeric bytes fall back to string", "DECIMAL", []byte("not-a-number"), "not-a-number"},
+		{"non-byte values pass through unchanged", "DECIMAL", 42, 42},
+		{"nil passes through unchanged", "DECIMAL", nil, nil},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := normalizeMySQL(tc.dbType, tc.in)
+			if got != tc.want {
+				t.Errorf("normalizeMySQL(%q, %#v) = %#v, want %#v", tc.dbType, tc.in, got, tc.want)
+			}
+		})
+	}
+}
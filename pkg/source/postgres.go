@@ -0,0 +1,41 @@
+package source
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	_ "github.com/lib/pq"
+)
+
+// NewPostgres opens a Postgres source.
+func NewPostgres(cfg DSNConfig) (Source, error) {
+	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s",
+		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName)
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open postgres: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("ping postgres: %w", err)
+	}
+	return &sqlSource{db: db, normalize: normalizePostgres}, nil
+}
+
+// normalizePostgres converts lib/pq's []byte encoding of NUMERIC/DECIMAL
+// columns into a float64, the same quirk normalizeMySQL handles for MySQL,
+// so the sheet writer gets a typed number instead of a quoted string.
+func normalizePostgres(dbType string, v interface{}) interface{} {
+	b, ok := v.([]byte)
+	if !ok {
+		return v
+	}
+	switch dbType {
+	case "NUMERIC", "DECIMAL":
+		if f, err := strconv.ParseFloat(string(b), 64); err == nil {
+			return f
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,27 @@
+package source
+
+import "testing"
+
+func TestNormalizePostgres(t *testing.T) {
+	cases := []struct {
+		name   string
+		dbType string
+		in     interface{}
+		want   interface{}
+	}{
+		{"numeric bytes convert to float64", "NUMERIC", []byte("3.14"), 3.14},
+		{"decimal bytes convert to float64", "DECIMAL", []byte("-7"), float64(-7)},
+		{"text bytes stay a string", "TEXT", []byte("hello"), "hello"},
+		{"unparseable numeric bytes fall back to string", "NUMERIC", []byte("NaN-ish"), "NaN-ish"},
+		{"non-byte values pass through unchanged", "NUMERIC", true, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := normalizePostgres(tc.dbType, tc.in)
+			if got != tc.want {
+				t.Errorf("normalizePostgres(%q, %#v) = %#v, want %#v", tc.dbType, tc.in, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+// Package source abstracts the SQL databases a loader can pull rows from,
+// so one run can ship data from multiple heterogeneous databases into
+// different spreadsheets.
+package source
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Rows is the cursor a loader iterates to build sheet rows. Values returns
+// already-normalized, typed Go values for the current row instead of the
+// classic Scan(dest...) pointer pattern, so per-driver quirks are resolved
+// once here rather than in every loader.
+type Rows interface {
+	Next() bool
+	Columns() ([]string, error)
+	Values() ([]interface{}, error)
+	Err() error
+	Close() error
+}
+
+// Source is a named SQL data source a loader can query.
+type Source interface {
+	Query(ctx context.Context, query string) (Rows, error)
+	Close() error
+}
+
+// Config is one entry under the top-level `sources:` map in config.yml.
+type Config struct {
+	Driver   string
+	Host     string
+	Port     int
+	User     string
+	Password string
+	DBName   string
+	// Path is the database file path, used by the sqlite driver only.
+	Path string
+}
+
+// New builds the Source named by cfg.Driver.
+func New(cfg Config) (Source, error) {
+	dsn := DSNConfig{
+		Host:     cfg.Host,
+		Port:     cfg.Port,
+		User:     cfg.User,
+		Password: cfg.Password,
+		DBName:   cfg.DBName,
+	}
+	switch cfg.Driver {
+	case "postgres":
+		return NewPostgres(dsn)
+	case "mysql":
+		return NewMySQL(dsn)
+	case "mssql":
+		return NewMSSQL(dsn)
+	case "sqlite":
+		return NewSQLite(cfg.Path)
+	default:
+		return nil, fmt.Errorf("unknown source driver %q", cfg.Driver)
+	}
+}
+
+// DSNConfig holds the connection parameters shared by the network-based
+// drivers (postgres, mysql, mssql).
+type DSNConfig struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	DBName   string
+}
+
+// normalizeFunc adjusts a single scanned value for driver-specific quirks,
+// given the column's reported database type name (sql.ColumnType's
+// DatabaseTypeName, e.g. "NUMERIC" or "DECIMAL"). It takes the type name
+// rather than *sql.ColumnType itself so the per-driver normalize functions
+// stay plain, table-testable functions.
+type normalizeFunc func(dbType string, v interface{}) interface{}
+
+// sqlSource adapts a database/sql *sql.DB into a Source, applying normalize
+// to every scanned value.
+type sqlSource struct {
+	db        *sql.DB
+	normalize normalizeFunc
+}
+
+func (s *sqlSource) Query(ctx context.Context, query string) (Rows, error) {
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlRows{rows: rows, normalize: s.normalize}, nil
+}
+
+func (s *sqlSource) Close() error {
+	return s.db.Close()
+}
+
+// sqlRows adapts *sql.Rows to the Rows interface, scanning into []interface{}
+// and normalizing each value before handing it back.
+type sqlRows struct {
+	rows      *sql.Rows
+	columns   []string
+	colTypes  []*sql.ColumnType
+	normalize normalizeFunc
+}
+
+func (r *sqlRows) Columns() ([]string, error) {
+	if r.columns == nil {
+		cols, err := r.rows.Columns()
+		if err != nil {
+			return nil, err
+		}
+		r.columns = cols
+	}
+	return r.columns, nil
+}
+
+func (r *sqlRows) Next() bool { return r.rows.Next() }
+func (r *sqlRows) Err() error { return r.rows.Err() }
+func (r *sqlRows) Close() error { return r.rows.Close() }
+
+func (r *sqlRows) Values() ([]interface{}, error) {
+	columns, err := r.Columns()
+	if err != nil {
+		return nil, err
+	}
+	if r.colTypes == nil {
+		colTypes, err := r.rows.ColumnTypes()
+		if err != nil {
+			return nil, err
+		}
+		r.colTypes = colTypes
+	}
+
+	raw := make([]interface{}, len(columns))
+	ptrs := make([]interface{}, len(columns))
+	for i := range raw {
+		ptrs[i] = &raw[i]
+	}
+	if err := r.rows.Scan(ptrs...); err != nil {
+		return nil, err
+	}
+
+	out := make([]interface{}, len(columns))
+	for i, v := range raw {
+		if r.normalize != nil {
+			v = r.normalize(r.colTypes[i].DatabaseTypeName(), v)
+		}
+		out[i] = v
+	}
+	return out, nil
+}
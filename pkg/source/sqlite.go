@@ -0,0 +1,20 @@
+package source
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// NewSQLite opens a SQLite source backed by the file at path.
+func NewSQLite(path string) (Source, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("ping sqlite: %w", err)
+	}
+	return &sqlSource{db: db, normalize: nil}, nil
+}